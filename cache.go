@@ -0,0 +1,70 @@
+package dataloadgen
+
+import "time"
+
+// CacheEntry is what a Loader stores per key once its batch has resolved: the
+// result itself, alongside when it should be evicted. A zero ExpiresAt means
+// the entry never expires on its own (the default, unless WithCacheTTL or
+// WithErrorTTL is used).
+type CacheEntry[ValueT any] struct {
+	Value     ValueT
+	Err       error
+	ExpiresAt time.Time
+}
+
+// Cache is the storage backend for a Loader's per-key cache of resolved
+// results. The default is an unbounded in-memory map, matching the original
+// behavior; implement this to swap in an LRU with a size cap, a
+// Redis/memcached-backed cache for cross-process sharing, or a null cache
+// that never retains anything for per-request loaders. Use WithCache to
+// install a custom implementation.
+//
+// Implementations must be safe for concurrent use.
+type Cache[KeyT comparable, ValueT any] interface {
+	Get(key KeyT) (CacheEntry[ValueT], bool)
+	Set(key KeyT, entry CacheEntry[ValueT])
+	Delete(key KeyT)
+}
+
+// mapCache is the default Cache implementation: an unbounded in-memory map.
+// All access happens while the owning Loader's mutex is held, so it needs no
+// locking of its own.
+type mapCache[KeyT comparable, ValueT any] struct {
+	entries map[KeyT]CacheEntry[ValueT]
+}
+
+func newMapCache[KeyT comparable, ValueT any]() *mapCache[KeyT, ValueT] {
+	return &mapCache[KeyT, ValueT]{entries: map[KeyT]CacheEntry[ValueT]{}}
+}
+
+func (c *mapCache[KeyT, ValueT]) Get(key KeyT) (CacheEntry[ValueT], bool) {
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *mapCache[KeyT, ValueT]) Set(key KeyT, entry CacheEntry[ValueT]) {
+	c.entries[key] = entry
+}
+
+func (c *mapCache[KeyT, ValueT]) Delete(key KeyT) {
+	delete(c.entries, key)
+}
+
+// reapExpired evicts every entry whose ExpiresAt has passed. It's an
+// optional extension that the background reaper uses when the Cache has it;
+// mapCache has it since enumerating its keys is cheap. The method is
+// unexported, so only Cache types in this package can provide it; for any
+// other Cache the reaper does nothing and lazy eviction on Load applies.
+func (c *mapCache[KeyT, ValueT]) reapExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cacheReaper is implemented by Cache backends that support periodic sweeps
+// for expired entries.
+type cacheReaper interface {
+	reapExpired(now time.Time)
+}
@@ -0,0 +1,104 @@
+package dataloadgen_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// nullCache never retains anything, forcing every Load to hit the fetcher.
+// This is the kind of backend WithCache exists to support for per-request
+// loaders that shouldn't cache across requests.
+type nullCache[KeyT comparable, ValueT any] struct{}
+
+func (nullCache[KeyT, ValueT]) Get(KeyT) (dataloadgen.CacheEntry[ValueT], bool) {
+	return dataloadgen.CacheEntry[ValueT]{}, false
+}
+func (nullCache[KeyT, ValueT]) Set(KeyT, dataloadgen.CacheEntry[ValueT]) {}
+func (nullCache[KeyT, ValueT]) Delete(KeyT)                              {}
+
+func TestWithCacheNullCacheDisablesCaching(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	var mu sync.Mutex
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		return keys, nil
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithCache[string, string](nullCache[string, string]{}),
+	)
+
+	_, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	_, err = dl.Load(ctx, "K1")
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 2, fetches, "a null cache never short-circuits the fetch")
+}
+
+// recordingCache wraps an in-memory map and records Get/Set/Delete calls, to
+// prove the Loader actually delegates through the Cache interface instead of
+// reaching into its own storage.
+type recordingCache[KeyT comparable, ValueT any] struct {
+	mu      sync.Mutex
+	entries map[KeyT]dataloadgen.CacheEntry[ValueT]
+	gets    int
+	sets    int
+}
+
+func (c *recordingCache[KeyT, ValueT]) Get(key KeyT) (dataloadgen.CacheEntry[ValueT], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *recordingCache[KeyT, ValueT]) Set(key KeyT, entry dataloadgen.CacheEntry[ValueT]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sets++
+	if c.entries == nil {
+		c.entries = map[KeyT]dataloadgen.CacheEntry[ValueT]{}
+	}
+	c.entries[key] = entry
+}
+
+func (c *recordingCache[KeyT, ValueT]) Delete(key KeyT) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+func TestWithCacheCustomBackendIsUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := &recordingCache[string, string]{}
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		return keys, nil
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithCache[string, string](cache),
+	)
+
+	v, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "K1", v)
+
+	v, err = dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "K1", v, "second load is served from the custom cache")
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	require.Equal(t, 1, cache.sets)
+	require.GreaterOrEqual(t, cache.gets, 2)
+}
@@ -0,0 +1,52 @@
+package dataloadgen_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vikstrous/dataloadgen"
+)
+
+type ctxKey struct{}
+
+func TestLoaderWithContext(t *testing.T) {
+	var gotFromBatch string
+
+	dl := dataloadgen.NewLoaderWithContext(func(ctx context.Context, keys []string) ([]string, []error) {
+		if v, ok := ctx.Value(ctxKey{}).(string); ok {
+			gotFromBatch = v
+		}
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = "value-" + key
+		}
+		return values, nil
+	}, dataloadgen.WithWait(10*time.Millisecond))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace-id-123")
+	v, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "value-K1", v)
+	require.Equal(t, "trace-id-123", gotFromBatch)
+}
+
+func TestLoaderWithContextCancellationDoesNotCancelBatch(t *testing.T) {
+	dl := dataloadgen.NewLoaderWithContext(func(ctx context.Context, keys []string) ([]string, []error) {
+		require.NoError(t, ctx.Err())
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = "value-" + key
+		}
+		return values, nil
+	}, dataloadgen.WithWait(20*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	thunk := dl.LoadThunk(ctx, "K1")
+	cancel()
+
+	v, err := thunk()
+	require.NoError(t, err)
+	require.Equal(t, "value-K1", v)
+}
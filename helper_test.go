@@ -0,0 +1,7 @@
+package dataloadgen_test
+
+// Copied from benchmarks_test.go (not included in this checkout).
+type benchmarkUser struct {
+	Name string
+	ID   string
+}
@@ -0,0 +1,541 @@
+package dataloadgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	trace "go.opentelemetry.io/otel/trace"
+)
+
+// Option allows for configuration of loader fields.
+type Option func(*loaderConfig)
+
+// WithBatchCapacity sets the batch capacity. Default is 0 (unbounded)
+func WithBatchCapacity(c int) Option {
+	return func(l *loaderConfig) {
+		l.maxBatch = c
+	}
+}
+
+// WithWait sets the amount of time to wait before triggering a batch.
+// Default duration is 16 milliseconds.
+func WithWait(d time.Duration) Option {
+	return func(l *loaderConfig) {
+		l.wait = d
+	}
+}
+
+func WithTracer(tracer trace.Tracer) Option {
+	return func(l *loaderConfig) {
+		l.tracer = tracer
+	}
+}
+
+// WithCacheTTL sets how long a successfully loaded value stays in the cache
+// before it's evicted and re-fetched on the next Load. Default is 0, meaning
+// cached values never expire on their own and only go away via Clear.
+func WithCacheTTL(d time.Duration) Option {
+	return func(l *loaderConfig) {
+		l.cacheTTL = d
+	}
+}
+
+// WithErrorTTL sets how long a cached per-key error stays in the cache before
+// it's evicted, letting a later Load retry the key instead of replaying the
+// same error forever. Default is 0, meaning cached errors never expire on
+// their own. Has no effect if WithoutErrorCache is used.
+func WithErrorTTL(d time.Duration) Option {
+	return func(l *loaderConfig) {
+		l.errorTTL = d
+	}
+}
+
+// WithoutErrorCache disables caching of per-key errors entirely: a key that
+// comes back with an error is evicted as soon as its thunk is resolved, so
+// the next Load retries it instead of replaying the cached error.
+func WithoutErrorCache() Option {
+	return func(l *loaderConfig) {
+		l.disableErrorCache = true
+	}
+}
+
+// WithCache swaps the loader's cache backend. Default is an unbounded
+// in-memory map, matching the original behavior; see Cache for why you
+// might supply your own.
+func WithCache[KeyT comparable, ValueT any](cache Cache[KeyT, ValueT]) Option {
+	return func(l *loaderConfig) {
+		l.cache = cache
+	}
+}
+
+// WithOnBatch registers a callback invoked every time a batch is dispatched
+// to fetch, whether triggered by WithWait's timeout or by filling
+// WithBatchCapacity. It receives the batch's keys, how long fetch took, and
+// the whole-batch error if fetch returned exactly one error for the whole
+// batch (nil otherwise). Useful for exporting batch size and latency
+// metrics, or tracing.
+func WithOnBatch[KeyT comparable](fn func(keys []KeyT, duration time.Duration, err error)) Option {
+	return func(l *loaderConfig) {
+		l.onBatch = fn
+	}
+}
+
+// WithOnCacheHit registers a callback invoked whenever Load or LoadThunk
+// finds a non-expired cached result for key, before it's returned.
+func WithOnCacheHit[KeyT comparable](fn func(key KeyT)) Option {
+	return func(l *loaderConfig) {
+		l.onCacheHit = fn
+	}
+}
+
+// WithOnCacheMiss registers a callback invoked whenever Load or LoadThunk
+// finds no cached result for key and has to join or start a batch fetch.
+func WithOnCacheMiss[KeyT comparable](fn func(key KeyT)) Option {
+	return func(l *loaderConfig) {
+		l.onCacheMiss = fn
+	}
+}
+
+// NewLoader creates a new Loader given a fetch, wait, and maxBatch.
+// The fetch function doesn't see the context passed to Load; use
+// NewLoaderWithContext if it needs to propagate deadlines, tracing spans or
+// auth metadata into the underlying data source.
+func NewLoader[KeyT comparable, ValueT any](fetch func(keys []KeyT) ([]ValueT, []error), options ...Option) *Loader[KeyT, ValueT] {
+	return NewLoaderWithContext(func(_ context.Context, keys []KeyT) ([]ValueT, []error) {
+		return fetch(keys)
+	}, options...)
+}
+
+// NewLoaderWithContext creates a new Loader given a fetch, wait, and
+// maxBatch, like NewLoader, but fetch also receives a context derived from
+// whichever caller's Load started the batch it's fetching. That context
+// keeps the caller's values (tracing spans, auth metadata, ...) but is
+// detached from its cancellation and deadline, since other callers may still
+// be waiting on the same batch.
+func NewLoaderWithContext[KeyT comparable, ValueT any](fetch func(ctx context.Context, keys []KeyT) ([]ValueT, []error), options ...Option) *Loader[KeyT, ValueT] {
+	config := &loaderConfig{
+		wait:     16 * time.Millisecond,
+		maxBatch: 0, //unlimited
+	}
+	for _, o := range options {
+		o(config)
+	}
+	cache, _ := config.cache.(Cache[KeyT, ValueT])
+	if cache == nil {
+		cache = newMapCache[KeyT, ValueT]()
+	}
+	onBatch, _ := config.onBatch.(func(keys []KeyT, duration time.Duration, err error))
+	onCacheHit, _ := config.onCacheHit.(func(key KeyT))
+	onCacheMiss, _ := config.onCacheMiss.(func(key KeyT))
+	l := &Loader[KeyT, ValueT]{
+		fetch:        fetch,
+		loaderConfig: config,
+		cache:        cache,
+		inFlight:     map[KeyT]func() (ValueT, error){},
+		closeCh:      make(chan struct{}),
+		onBatch:      onBatch,
+		onCacheHit:   onCacheHit,
+		onCacheMiss:  onCacheMiss,
+	}
+	if config.cacheTTL > 0 || config.errorTTL > 0 {
+		go l.reapExpiredEntries()
+	}
+	return l
+}
+
+type loaderConfig struct {
+	// how long to done before sending a batch
+	wait time.Duration
+
+	// this will limit the maximum number of keys to send in one batch, 0 = no limit
+	maxBatch int
+
+	tracer trace.Tracer
+
+	// how long a cached value or error stays in the cache before it's
+	// lazily evicted, 0 = never expires on its own
+	cacheTTL time.Duration
+	errorTTL time.Duration
+
+	// disables caching of per-key errors entirely
+	disableErrorCache bool
+
+	// type-erased Cache[KeyT, ValueT] installed by WithCache; nil means use
+	// the default in-memory cache
+	cache interface{}
+
+	// type-erased observability hooks installed by WithOnBatch,
+	// WithOnCacheHit and WithOnCacheMiss; nil means the hook isn't installed
+	onBatch     interface{}
+	onCacheHit  interface{}
+	onCacheMiss interface{}
+}
+
+// Loader batches and caches requests
+type Loader[KeyT comparable, ValueT any] struct {
+	// this method provides the data for the loader
+	fetch func(ctx context.Context, keys []KeyT) ([]ValueT, []error)
+
+	*loaderConfig
+
+	// INTERNAL
+
+	// cache holds resolved results (value/err/expiresAt), keyed by KeyT. It's
+	// swappable via WithCache; default is an unbounded in-memory map.
+	cache Cache[KeyT, ValueT]
+
+	// inFlight holds a thunk per key that's been requested but whose batch
+	// hasn't resolved yet, so concurrent callers for the same key share one
+	// fetch instead of each starting their own. Resolved results move into
+	// cache and are removed from here.
+	inFlight map[KeyT]func() (ValueT, error)
+
+	// the current batch. keys will continue to be collected until timeout is hit,
+	// then everything will be sent to the fetch method and out to the listeners
+	batch *loaderBatch[KeyT, ValueT]
+
+	// mutex to prevent races
+	mu sync.Mutex
+
+	// closeCh is closed by Close to stop the background reaper goroutine
+	closeCh   chan struct{}
+	closeOnce sync.Once
+
+	// observability hooks, nil if not installed
+	onBatch     func(keys []KeyT, duration time.Duration, err error)
+	onCacheHit  func(key KeyT)
+	onCacheMiss func(key KeyT)
+}
+
+type loaderBatch[KeyT comparable, ValueT any] struct {
+	keys          []KeyT
+	results       []ValueT
+	errors        []error
+	fetchExecuted bool
+	done          chan struct{}
+	firstContext  context.Context
+	contexts      []context.Context
+	spans         []trace.Span
+}
+
+// detachedContext carries parent's values without its cancellation or
+// deadline, so a caller cancelling its own context doesn't cancel fetch for
+// every other caller waiting on the same batch.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detach(parent context.Context) context.Context {
+	return detachedContext{parent: parent}
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+func (d detachedContext) Value(key interface{}) interface{}     { return d.parent.Value(key) }
+
+// Load a ValueT by key, batching and caching will be applied automatically
+func (l *Loader[KeyT, ValueT]) Load(ctx context.Context, key KeyT) (ValueT, error) {
+	return l.LoadThunk(ctx, key)()
+}
+
+// LoadThunk returns a function that when called will block waiting for a ValueT.
+// This method should be used if you want one goroutine to make requests to many
+// different data loaders without blocking until the thunk is called.
+func (l *Loader[KeyT, ValueT]) LoadThunk(ctx context.Context, key KeyT) func() (ValueT, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.cache.Get(key); ok {
+		if entry.ExpiresAt.IsZero() || time.Now().Before(entry.ExpiresAt) {
+			if l.onCacheHit != nil {
+				l.onCacheHit(key)
+			}
+			value, err := entry.Value, entry.Err
+			return func() (ValueT, error) { return value, err }
+		}
+		l.cache.Delete(key)
+	}
+	if l.onCacheMiss != nil {
+		l.onCacheMiss(key)
+	}
+	if it, ok := l.inFlight[key]; ok {
+		return it
+	}
+
+	l.startBatch(ctx)
+
+	if l.tracer != nil {
+		_, loadSpan := l.tracer.Start(ctx, "dataloadgen.load")
+		defer loadSpan.End()
+		l.batch.contexts = append(l.batch.contexts, ctx)
+		_, waitSpan := l.tracer.Start(ctx, "dataloadgen.wait")
+		l.batch.spans = append(l.batch.spans, waitSpan)
+	}
+
+	batch := l.batch
+	pos := l.addKeyToBatch(batch, key)
+
+	thunk := func() (ValueT, error) {
+		<-batch.done
+
+		var data ValueT
+
+		// Return early if there's a single error and it's not nil
+		if len(batch.errors) == 1 && batch.errors[0] != nil {
+			return data, batch.errors[0]
+		}
+
+		// If the batch function returned the wrong number of responses, return an error to all callers
+		if len(batch.results) != len(batch.keys) {
+			return data, fmt.Errorf("bug in loader: %d values returned for %d keys", len(batch.results), len(batch.keys))
+		}
+
+		if pos < len(batch.results) {
+			data = batch.results[pos]
+		}
+
+		var err error
+		if batch.errors != nil {
+			err = batch.errors[pos]
+		}
+
+		return data, err
+	}
+	cachedThunk := func() (ValueT, error) {
+		data, err := thunk()
+
+		l.mu.Lock()
+		delete(l.inFlight, key)
+		if err != nil && l.disableErrorCache {
+			l.cache.Delete(key)
+		} else {
+			entry := CacheEntry[ValueT]{Value: data, Err: err}
+			ttl := l.cacheTTL
+			if err != nil {
+				ttl = l.errorTTL
+			}
+			if ttl > 0 {
+				entry.ExpiresAt = time.Now().Add(ttl)
+			}
+			l.cache.Set(key, entry)
+		}
+		l.mu.Unlock()
+
+		return data, err
+	}
+	l.inFlight[key] = cachedThunk
+	return cachedThunk
+}
+
+// LoadAll fetches many keys at once. It will be broken into appropriate sized
+// sub batches depending on how the loader is configured
+func (l *Loader[KeyT, ValueT]) LoadAll(ctx context.Context, keys []KeyT) ([]ValueT, []error) {
+	thunks := make([]func() (ValueT, error), len(keys))
+
+	for i, key := range keys {
+		thunks[i] = l.LoadThunk(ctx, key)
+	}
+
+	values := make([]ValueT, len(keys))
+	errors := make([]error, len(keys))
+	allNil := true
+	for i, thunk := range thunks {
+		values[i], errors[i] = thunk()
+		if errors[i] != nil {
+			allNil = false
+		}
+	}
+	if allNil {
+		return values, nil
+	}
+	return values, errors
+}
+
+// LoadAllThunk returns a function that when called will block waiting for a ValueT.
+// This method should be used if you want one goroutine to make requests to many
+// different data loaders without blocking until the thunk is called.
+func (l *Loader[KeyT, ValueT]) LoadAllThunk(ctx context.Context, keys []KeyT) func() ([]ValueT, []error) {
+	thunks := make([]func() (ValueT, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.LoadThunk(ctx, key)
+	}
+	return func() ([]ValueT, []error) {
+		values := make([]ValueT, len(keys))
+		errors := make([]error, len(keys))
+		for i, thunk := range thunks {
+			values[i], errors[i] = thunk()
+		}
+		return values, errors
+	}
+}
+
+// Prime the cache with the provided key and value. If the key already exists, no change is made
+// and false is returned.
+// (To forcefully prime the cache, call loader.Clear(key) first, then loader.Prime(key, value).)
+func (l *Loader[KeyT, ValueT]) Prime(key KeyT, value ValueT) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, found := l.inFlight[key]; found {
+		return false
+	}
+	if _, found := l.cache.Get(key); found {
+		return false
+	}
+	l.cache.Set(key, CacheEntry[ValueT]{Value: value})
+	return true
+}
+
+// Clear the value at key from the cache, if it exists
+func (l *Loader[KeyT, ValueT]) Clear(key KeyT) {
+	l.mu.Lock()
+	delete(l.inFlight, key)
+	l.cache.Delete(key)
+	l.mu.Unlock()
+}
+
+// Close stops the background goroutine that reaps expired cache entries when
+// WithCacheTTL or WithErrorTTL is used. It's safe to call Close more than
+// once, and safe to skip entirely if neither TTL option was configured.
+func (l *Loader[KeyT, ValueT]) Close() {
+	l.closeOnce.Do(func() {
+		close(l.closeCh)
+	})
+}
+
+// reapExpiredEntries periodically evicts expired cache entries in the
+// background, so keys that are never Loaded again still get cleaned up
+// instead of lingering until process exit. Load itself also evicts lazily,
+// so this is only an optimization for memory use, not a correctness
+// requirement. If the configured Cache doesn't support enumerating its
+// entries, this is a no-op and only lazy eviction applies.
+func (l *Loader[KeyT, ValueT]) reapExpiredEntries() {
+	reaper, ok := l.cache.(cacheReaper)
+	if !ok {
+		return
+	}
+	ticker := time.NewTicker(l.reapInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.closeCh:
+			return
+		case now := <-ticker.C:
+			l.mu.Lock()
+			reaper.reapExpired(now)
+			l.mu.Unlock()
+		}
+	}
+}
+
+// reapInterval picks how often the background reaper sweeps for expired
+// entries, based on whichever configured TTL is shortest.
+func (l *Loader[KeyT, ValueT]) reapInterval() time.Duration {
+	interval := l.cacheTTL
+	if interval == 0 || (l.errorTTL > 0 && l.errorTTL < interval) {
+		interval = l.errorTTL
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return interval
+}
+
+func (l *Loader[KeyT, ValueT]) startBatch(ctx context.Context) {
+	if l.batch == nil {
+		batch := &loaderBatch[KeyT, ValueT]{
+			done:         make(chan struct{}),
+			firstContext: detach(ctx),
+		}
+		l.batch = batch
+		go func(l *Loader[KeyT, ValueT]) {
+			time.Sleep(l.wait)
+			l.mu.Lock()
+
+			// we must have hit a batch limit and are already finalizing this batch
+			if batch.fetchExecuted {
+				l.mu.Unlock()
+				return
+			}
+
+			ctxs := l.batch.contexts
+			spans := l.batch.spans
+
+			l.batch = nil
+			l.mu.Unlock()
+
+			if l.tracer != nil {
+				for _, ctx := range ctxs {
+					_, span := l.tracer.Start(ctx, "dataloadgen.fetch.timelimit")
+					defer span.End()
+				}
+			}
+
+			start := time.Now()
+			batch.results, batch.errors = l.fetch(batch.firstContext, batch.keys)
+			duration := time.Since(start)
+
+			if l.tracer != nil {
+				for _, span := range spans {
+					span.End()
+				}
+			}
+
+			if l.onBatch != nil {
+				var batchErr error
+				if len(batch.errors) == 1 && batch.errors[0] != nil {
+					batchErr = batch.errors[0]
+				}
+				l.onBatch(batch.keys, duration, batchErr)
+			}
+
+			close(batch.done)
+		}(l)
+	}
+}
+
+// addKeyToBatch appends key to the batch and returns its position in it. It
+// does not look for an earlier copy of key in the batch; LoadThunk dedupes
+// concurrent requests for a key through inFlight.
+func (l *Loader[KeyT, ValueT]) addKeyToBatch(b *loaderBatch[KeyT, ValueT], key KeyT) int {
+	pos := len(b.keys)
+	b.keys = append(b.keys, key)
+
+	if l.maxBatch != 0 && pos >= l.maxBatch-1 {
+		ctxs := l.batch.contexts
+		spans := l.batch.spans
+		b.fetchExecuted = true
+		l.batch = nil
+		go func(l *Loader[KeyT, ValueT], ctxs []context.Context) {
+			if l.tracer != nil {
+				for _, ctx := range ctxs {
+					_, span := l.tracer.Start(ctx, "dataloadgen.fetch.keylimit")
+					defer span.End()
+				}
+			}
+
+			start := time.Now()
+			b.results, b.errors = l.fetch(b.firstContext, b.keys)
+			duration := time.Since(start)
+
+			if l.tracer != nil {
+				for _, span := range spans {
+					span.End()
+				}
+			}
+
+			if l.onBatch != nil {
+				var batchErr error
+				if len(b.errors) == 1 && b.errors[0] != nil {
+					batchErr = b.errors[0]
+				}
+				l.onBatch(b.keys, duration, batchErr)
+			}
+
+			close(b.done)
+		}(l, ctxs)
+	}
+
+	return pos
+}
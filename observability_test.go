@@ -0,0 +1,80 @@
+package dataloadgen_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vikstrous/dataloadgen"
+)
+
+func TestWithOnBatchReportsKeysDurationAndError(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var gotKeys [][]string
+	var gotErrs []error
+
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		time.Sleep(5 * time.Millisecond)
+		if len(keys) == 1 && keys[0] == "E1" {
+			return nil, []error{fmt.Errorf("boom")}
+		}
+		return keys, nil
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithOnBatch(func(keys []string, duration time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			gotKeys = append(gotKeys, keys)
+			gotErrs = append(gotErrs, err)
+			require.GreaterOrEqual(t, duration, 5*time.Millisecond)
+		}),
+	)
+
+	_, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+
+	_, err = dl.Load(ctx, "E1")
+	require.Error(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, [][]string{{"K1"}, {"E1"}}, gotKeys)
+	require.Nil(t, gotErrs[0])
+	require.EqualError(t, gotErrs[1], "boom")
+}
+
+func TestWithOnCacheHitAndMiss(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var hits, misses []string
+
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		return keys, nil
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithOnCacheHit(func(key string) {
+			mu.Lock()
+			defer mu.Unlock()
+			hits = append(hits, key)
+		}),
+		dataloadgen.WithOnCacheMiss(func(key string) {
+			mu.Lock()
+			defer mu.Unlock()
+			misses = append(misses, key)
+		}),
+	)
+
+	_, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	_, err = dl.Load(ctx, "K1")
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"K1"}, misses)
+	require.Equal(t, []string{"K1"}, hits)
+}
@@ -0,0 +1,65 @@
+package dataloadgen
+
+import "context"
+
+// SliceLoader batches and caches requests where each key maps to a slice of
+// values instead of a single value - the classic "comments for a post" or
+// "orders for a customer" one-to-many case. It wraps a Loader[KeyT, []ValueT]
+// internally, so it gets the same batching, caching and error semantics.
+type SliceLoader[KeyT comparable, ValueT any] struct {
+	loader *Loader[KeyT, []ValueT]
+}
+
+// NewSliceLoader creates a new SliceLoader given a fetch, wait, and maxBatch
+func NewSliceLoader[KeyT comparable, ValueT any](fetch func(keys []KeyT) ([][]ValueT, []error), options ...Option) *SliceLoader[KeyT, ValueT] {
+	return &SliceLoader[KeyT, ValueT]{
+		loader: NewLoader(fetch, options...),
+	}
+}
+
+// NewSliceLoaderWithContext creates a new SliceLoader given a fetch, wait,
+// and maxBatch, like NewSliceLoader, but fetch also receives the context
+// derived from whichever caller started the batch it's fetching; see
+// NewLoaderWithContext.
+func NewSliceLoaderWithContext[KeyT comparable, ValueT any](fetch func(ctx context.Context, keys []KeyT) ([][]ValueT, []error), options ...Option) *SliceLoader[KeyT, ValueT] {
+	return &SliceLoader[KeyT, ValueT]{
+		loader: NewLoaderWithContext(fetch, options...),
+	}
+}
+
+// Load the slice of ValueT for key, batching and caching will be applied automatically
+func (l *SliceLoader[KeyT, ValueT]) Load(ctx context.Context, key KeyT) ([]ValueT, error) {
+	return l.loader.Load(ctx, key)
+}
+
+// LoadThunk returns a function that when called will block waiting for a []ValueT.
+// This method should be used if you want one goroutine to make requests to many
+// different data loaders without blocking until the thunk is called.
+func (l *SliceLoader[KeyT, ValueT]) LoadThunk(ctx context.Context, key KeyT) func() ([]ValueT, error) {
+	return l.loader.LoadThunk(ctx, key)
+}
+
+// LoadAll fetches many keys at once. It will be broken into appropriate sized
+// sub batches depending on how the loader is configured
+func (l *SliceLoader[KeyT, ValueT]) LoadAll(ctx context.Context, keys []KeyT) ([][]ValueT, []error) {
+	return l.loader.LoadAll(ctx, keys)
+}
+
+// LoadAllThunk returns a function that when called will block waiting for the
+// []ValueT for every key. This method should be used if you want one goroutine
+// to make requests to many different data loaders without blocking until the
+// thunk is called.
+func (l *SliceLoader[KeyT, ValueT]) LoadAllThunk(ctx context.Context, keys []KeyT) func() ([][]ValueT, []error) {
+	return l.loader.LoadAllThunk(ctx, keys)
+}
+
+// Prime the cache with the provided key and slice of values. If the key already
+// exists, no change is made and false is returned.
+func (l *SliceLoader[KeyT, ValueT]) Prime(key KeyT, value []ValueT) bool {
+	return l.loader.Prime(key, value)
+}
+
+// Clear the value at key from the cache, if it exists
+func (l *SliceLoader[KeyT, ValueT]) Clear(key KeyT) {
+	l.loader.Clear(key)
+}
@@ -0,0 +1,91 @@
+package dataloadgen_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vikstrous/dataloadgen"
+)
+
+func TestSliceLoader(t *testing.T) {
+	ctx := context.Background()
+	var fetches [][]string
+	var mu sync.Mutex
+	dl := dataloadgen.NewSliceLoader(func(keys []string) ([][]string, []error) {
+		mu.Lock()
+		fetches = append(fetches, keys)
+		mu.Unlock()
+
+		comments := make([][]string, len(keys))
+		errors := make([]error, len(keys))
+
+		for i, key := range keys {
+			if strings.HasPrefix(key, "E") {
+				errors[i] = fmt.Errorf("post not found")
+				continue
+			}
+			comments[i] = []string{key + "-c1", key + "-c2"}
+		}
+		return comments, errors
+	},
+		dataloadgen.WithBatchCapacity(5),
+		dataloadgen.WithWait(10*time.Millisecond),
+	)
+
+	t.Run("load one key returns its slice of values", func(t *testing.T) {
+		comments, err := dl.Load(ctx, "P1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"P1-c1", "P1-c2"}, comments)
+	})
+
+	t.Run("load failed key returns the error", func(t *testing.T) {
+		comments, err := dl.Load(ctx, "E1")
+		require.Error(t, err)
+		require.Nil(t, comments)
+	})
+
+	t.Run("load all batches multiple keys into one fetch", func(t *testing.T) {
+		mu.Lock()
+		fetches = nil
+		mu.Unlock()
+
+		comments, errs := dl.LoadAll(ctx, []string{"P2", "E2", "P3"})
+		require.NoError(t, errs[0])
+		require.Error(t, errs[1])
+		require.NoError(t, errs[2])
+		require.Equal(t, []string{"P2-c1", "P2-c2"}, comments[0])
+		require.Equal(t, []string{"P3-c1", "P3-c2"}, comments[2])
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, fetches, 1)
+		require.Len(t, fetches[0], 3)
+	})
+
+	t.Run("prime avoids a round trip", func(t *testing.T) {
+		mu.Lock()
+		fetches = nil
+		mu.Unlock()
+
+		dl.Prime("P99", []string{"primed-comment"})
+		comments, err := dl.Load(ctx, "P99")
+		require.NoError(t, err)
+		require.Equal(t, []string{"primed-comment"}, comments)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, fetches, 0)
+	})
+
+	t.Run("clear forces a fresh fetch", func(t *testing.T) {
+		dl.Clear("P99")
+		comments, err := dl.Load(ctx, "P99")
+		require.NoError(t, err)
+		require.Equal(t, []string{"P99-c1", "P99-c2"}, comments)
+	})
+}
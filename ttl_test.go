@@ -0,0 +1,112 @@
+package dataloadgen_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vikstrous/dataloadgen"
+)
+
+func TestCacheTTLExpiresValues(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	var mu sync.Mutex
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		mu.Lock()
+		fetches++
+		n := fetches
+		mu.Unlock()
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = fmt.Sprintf("%s-v%d", key, n)
+		}
+		return values, nil
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithCacheTTL(20*time.Millisecond),
+	)
+
+	v, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "K1-v1", v)
+
+	v, err = dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "K1-v1", v, "cached value is reused before it expires")
+
+	time.Sleep(30 * time.Millisecond)
+
+	v, err = dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "K1-v2", v, "expired value triggers a fresh fetch")
+}
+
+func TestErrorTTLExpiresErrors(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	var mu sync.Mutex
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		mu.Lock()
+		fetches++
+		n := fetches
+		mu.Unlock()
+		errors := make([]error, len(keys))
+		for i := range keys {
+			errors[i] = fmt.Errorf("attempt %d failed", n)
+		}
+		return make([]string, len(keys)), errors
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithErrorTTL(20*time.Millisecond),
+	)
+
+	_, err := dl.Load(ctx, "K1")
+	require.EqualError(t, err, "attempt 1 failed")
+
+	_, err = dl.Load(ctx, "K1")
+	require.EqualError(t, err, "attempt 1 failed", "cached error is replayed before it expires")
+
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = dl.Load(ctx, "K1")
+	require.EqualError(t, err, "attempt 2 failed", "expired error triggers a fresh fetch")
+}
+
+func TestWithoutErrorCacheRetriesImmediately(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	var mu sync.Mutex
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		mu.Lock()
+		fetches++
+		n := fetches
+		mu.Unlock()
+		if n == 1 {
+			return make([]string, len(keys)), []error{fmt.Errorf("not ready yet")}
+		}
+		return []string{"ready"}, nil
+	},
+		dataloadgen.WithWait(time.Millisecond),
+		dataloadgen.WithoutErrorCache(),
+	)
+
+	_, err := dl.Load(ctx, "K1")
+	require.EqualError(t, err, "not ready yet")
+
+	v, err := dl.Load(ctx, "K1")
+	require.NoError(t, err)
+	require.Equal(t, "ready", v)
+}
+
+func TestCloseStopsReaper(t *testing.T) {
+	dl := dataloadgen.NewLoader(func(keys []string) ([]string, []error) {
+		return make([]string, len(keys)), nil
+	}, dataloadgen.WithCacheTTL(time.Millisecond))
+
+	dl.Close()
+	dl.Close() // must be safe to call more than once
+}